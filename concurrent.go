@@ -0,0 +1,150 @@
+//
+// Copyright (c) 2020, Jason S. McMullan <jason.mcmullan@gmail.com>
+//
+
+package voctree
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"runtime"
+	"sync"
+)
+
+// ensureOctants returns the 8 top-level subtrees of node, splitting it
+// first if it is still a single uniform leaf or a Vocel8. Each returned
+// subtree can then be mutated independently.
+func ensureOctants(node Vocelish) (subtree [8]Vocelish) {
+	switch n := node.(type) {
+	case *VocelTree:
+		subtree = n.subtree
+	case *Vocel8:
+		for i, c := range n.pixel {
+			subtree[i] = &Vocel1{pixel: c}
+		}
+	default:
+		pixel, _ := leafColor(node)
+		for i := range subtree {
+			if pixel == DefaultColor {
+				subtree[i] = emptyNode
+			} else {
+				subtree[i] = &Vocel1{pixel: pixel}
+			}
+		}
+	}
+	return
+}
+
+// octantRange returns the [lo, hi) slice of a single axis of length total
+// covered by the half of the top-level octant identified by high.
+func octantRange(high bool, total, half int) (lo, hi int) {
+	if high {
+		lo, hi = half, total
+	} else {
+		lo, hi = 0, half
+	}
+	if lo > total {
+		lo = total
+	}
+	if hi > total {
+		hi = total
+	}
+	return
+}
+
+// SetPlanes ingests a batch of Z planes concurrently. The volume is
+// partitioned by top-level octant (one of the root's 8 children), each
+// built up by its own goroutine, then merged back into the root once all
+// of them finish.
+func (v *Voctree) SetPlanes(planes map[int]*image.Gray) (err error) {
+	if len(planes) == 0 {
+		return nil
+	}
+
+	size := v.Size()
+	maxZ := 0
+	for z, gray := range planes {
+		if len(gray.Pix) != size.X*size.Y {
+			return fmt.Errorf("pix: expected %v bytes, got %v", size.X*size.Y, len(gray.Pix))
+		}
+		if z > maxZ {
+			maxZ = z
+		}
+	}
+
+	v.resizeSideShift(maxZ)
+
+	if v.SideShift == 0 {
+		// A single-voxel tree has no top-level octants to partition.
+		for z, gray := range planes {
+			pix := gray.Pix
+			for y := 0; y < size.Y; y++ {
+				for x := 0; x < size.X; x++ {
+					v.Set(Point{X: uint16(x), Y: uint16(y), Z: uint16(z)}, color.Gray{Y: pix[y*size.X+x]})
+				}
+			}
+		}
+		return nil
+	}
+
+	octants := ensureOctants(v.Vocelish)
+	half := int(1) << (v.SideShift - 1)
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > 8 {
+		workers = 8
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan int, 8)
+	for i := 0; i < 8; i++ {
+		jobs <- i
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for octant := range jobs {
+				sub := octants[octant]
+
+				xlo, xhi := octantRange(octant&1 != 0, size.X, half)
+				ylo, yhi := octantRange(octant&2 != 0, size.Y, half)
+				highZ := octant&4 != 0
+
+				for z, gray := range planes {
+					if (z >= half) != highZ {
+						continue
+					}
+
+					pix := gray.Pix
+					for y := ylo; y < yhi; y++ {
+						for x := xlo; x < xhi; x++ {
+							cube := Cube{SideShift: v.SideShift, Point: Point{X: uint16(x), Y: uint16(y), Z: uint16(z)}}
+							_, here := octIndex(cube)
+							sub = sub.Set(here, color.Gray{Y: pix[y*size.X+x]})
+						}
+					}
+				}
+
+				mu.Lock()
+				octants[octant] = sub
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	v.Vocelish = coalesceOctant(octants)
+
+	return nil
+}
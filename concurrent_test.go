@@ -0,0 +1,98 @@
+//
+// Copyright (c) 2020, Jason S. McMullan <jason.mcmullan@gmail.com>
+//
+
+package voctree
+
+import (
+	"image"
+	"testing"
+)
+
+func TestVoctreeSetPlanes(t *testing.T) {
+	const testX = 64
+	const testY = 64
+	const testZ = 64
+
+	serial := NewVoctree(testX, testY)
+	parallel := NewVoctree(testX, testY)
+
+	planes := make(map[int]*image.Gray, testZ)
+	for z := 0; z < testZ; z++ {
+		gray := image.NewGray(image.Rect(0, 0, testX, testY))
+		for i := range gray.Pix {
+			if z < testZ/2 {
+				gray.Pix[i] = 0xff
+			}
+		}
+		planes[z] = gray
+
+		serial.SetPlane(z, gray)
+	}
+
+	if err := parallel.SetPlanes(planes); err != nil {
+		t.Fatalf("SetPlanes: %v", err)
+	}
+
+	for z := 0; z < testZ; z++ {
+		want := serial.GetPlane(z)
+		have := parallel.GetPlane(z)
+		for i := range want.Pix {
+			if want.Pix[i] != have.Pix[i] {
+				t.Fatalf("plane %v, pixel %v: expected %v, got %v", z, i, want.Pix[i], have.Pix[i])
+			}
+		}
+	}
+}
+
+func TestVoctreeSetPlanesEmpty(t *testing.T) {
+	v := NewVoctree(32, 32)
+	if err := v.SetPlanes(nil); err != nil {
+		t.Fatalf("SetPlanes(nil): %v", err)
+	}
+	if v.Nodes() != 0 {
+		t.Fatalf("expected an untouched tree, got %v nodes", v.Nodes())
+	}
+}
+
+func benchmarkPlanes(b *testing.B, size, depth int) map[int]*image.Gray {
+	planes := make(map[int]*image.Gray, depth)
+	for z := 0; z < depth; z++ {
+		gray := image.NewGray(image.Rect(0, 0, size, size))
+		for i := range gray.Pix {
+			gray.Pix[i] = byte((z + i) % 2 * 0xff)
+		}
+		planes[z] = gray
+	}
+	return planes
+}
+
+func BenchmarkSetPlaneSerial(b *testing.B) {
+	const size = 512
+	const depth = 16
+
+	planes := benchmarkPlanes(b, size, depth)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v := NewVoctree(size, size)
+		for z := 0; z < depth; z++ {
+			v.SetPlane(z, planes[z])
+		}
+	}
+}
+
+func BenchmarkSetPlanesConcurrent(b *testing.B) {
+	const size = 512
+	const depth = 16
+
+	planes := benchmarkPlanes(b, size, depth)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v := NewVoctree(size, size)
+		if err := v.SetPlanes(planes); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+//
+// Copyright (c) 2020, Jason S. McMullan <jason.mcmullan@gmail.com>
+//
+
+package mesh
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ezrec/voctree"
+)
+
+// bvhLeafSize bounds how many triangles a bvhNode leaf holds before it is
+// split further.
+const bvhLeafSize = 4
+
+// aabb is an axis-aligned bounding box in voxel space.
+type aabb struct {
+	min, max [3]float64
+}
+
+func cubeAABB(cube voctree.Cube) aabb {
+	size := float64(uint32(1) << cube.SideShift)
+	min := [3]float64{float64(cube.Point.X), float64(cube.Point.Y), float64(cube.Point.Z)}
+	return aabb{min: min, max: [3]float64{min[0] + size, min[1] + size, min[2] + size}}
+}
+
+func triBounds(tri Triangle) (box aabb) {
+	box.min, box.max = tri.V[0], tri.V[0]
+	for v := 1; v < 3; v++ {
+		for i := 0; i < 3; i++ {
+			if tri.V[v][i] < box.min[i] {
+				box.min[i] = tri.V[v][i]
+			}
+			if tri.V[v][i] > box.max[i] {
+				box.max[i] = tri.V[v][i]
+			}
+		}
+	}
+	return
+}
+
+func (a aabb) union(b aabb) (out aabb) {
+	for i := 0; i < 3; i++ {
+		out.min[i] = math.Min(a.min[i], b.min[i])
+		out.max[i] = math.Max(a.max[i], b.max[i])
+	}
+	return
+}
+
+func (a aabb) overlaps(b aabb) bool {
+	for i := 0; i < 3; i++ {
+		if a.max[i] < b.min[i] || a.min[i] > b.max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (a aabb) center() [3]float64 {
+	return [3]float64{
+		(a.min[0] + a.max[0]) / 2,
+		(a.min[1] + a.max[1]) / 2,
+		(a.min[2] + a.max[2]) / 2,
+	}
+}
+
+func (a aabb) half() [3]float64 {
+	return [3]float64{
+		(a.max[0] - a.min[0]) / 2,
+		(a.max[1] - a.min[1]) / 2,
+		(a.max[2] - a.min[2]) / 2,
+	}
+}
+
+func (a aabb) longestAxis() int {
+	ext := sub(a.max, a.min)
+	axis := 0
+	if ext[1] > ext[axis] {
+		axis = 1
+	}
+	if ext[2] > ext[axis] {
+		axis = 2
+	}
+	return axis
+}
+
+func triCentroid(tri Triangle) [3]float64 {
+	return [3]float64{
+		(tri.V[0][0] + tri.V[1][0] + tri.V[2][0]) / 3,
+		(tri.V[0][1] + tri.V[1][1] + tri.V[2][1]) / 3,
+		(tri.V[0][2] + tri.V[1][2] + tri.V[2][2]) / 3,
+	}
+}
+
+// bvhNode is a node of a simple recursive AABB split over a triangle
+// mesh, used to prune triangle/cube tests during voxelization.
+type bvhNode struct {
+	box      aabb
+	tris     []int // leaf only
+	children [2]*bvhNode
+}
+
+func buildBVH(tris []Triangle, idx []int) *bvhNode {
+	box := triBounds(tris[idx[0]])
+	for _, i := range idx[1:] {
+		box = box.union(triBounds(tris[i]))
+	}
+
+	if len(idx) <= bvhLeafSize {
+		return &bvhNode{box: box, tris: idx}
+	}
+
+	axis := box.longestAxis()
+	sort.Slice(idx, func(a, b int) bool {
+		return triCentroid(tris[idx[a]])[axis] < triCentroid(tris[idx[b]])[axis]
+	})
+
+	mid := len(idx) / 2
+	return &bvhNode{
+		box: box,
+		children: [2]*bvhNode{
+			buildBVH(tris, idx[:mid]),
+			buildBVH(tris, idx[mid:]),
+		},
+	}
+}
+
+// query appends the indices of every triangle whose bvh subtree overlaps
+// box to out.
+func (n *bvhNode) query(box aabb, out *[]int) {
+	if n == nil || !n.box.overlaps(box) {
+		return
+	}
+	if n.tris != nil {
+		*out = append(*out, n.tris...)
+		return
+	}
+	n.children[0].query(box, out)
+	n.children[1].query(box, out)
+}
@@ -0,0 +1,154 @@
+//
+// Copyright (c) 2020, Jason S. McMullan <jason.mcmullan@gmail.com>
+//
+
+package mesh
+
+import "math"
+
+func sub(a, b [3]float64) [3]float64 {
+	return [3]float64{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+}
+
+func cross(a, b [3]float64) [3]float64 {
+	return [3]float64{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+}
+
+func dot(a, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+// triangleIntersectsAABB is the standard Akenine-Moller
+// triangle/box separating-axis test: the 9 cross-product axes of the
+// triangle's edges against the box's axes, the 3 box-face axes, and the
+// triangle's own face normal.
+func triangleIntersectsAABB(tri Triangle, boxCenter, boxHalf [3]float64) bool {
+	v0 := sub(tri.V[0], boxCenter)
+	v1 := sub(tri.V[1], boxCenter)
+	v2 := sub(tri.V[2], boxCenter)
+
+	e0 := sub(v1, v0)
+	e1 := sub(v2, v1)
+	e2 := sub(v0, v2)
+
+	axes := [9][3]float64{
+		{0, -e0[2], e0[1]}, {0, -e1[2], e1[1]}, {0, -e2[2], e2[1]},
+		{e0[2], 0, -e0[0]}, {e1[2], 0, -e1[0]}, {e2[2], 0, -e2[0]},
+		{-e0[1], e0[0], 0}, {-e1[1], e1[0], 0}, {-e2[1], e2[0], 0},
+	}
+	for _, axis := range axes {
+		if !axisTestOverlap(axis, v0, v1, v2, boxHalf) {
+			return false
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		minV := math.Min(v0[i], math.Min(v1[i], v2[i]))
+		maxV := math.Max(v0[i], math.Max(v1[i], v2[i]))
+		if minV > boxHalf[i] || maxV < -boxHalf[i] {
+			return false
+		}
+	}
+
+	normal := cross(e0, e1)
+	if !planeBoxOverlap(normal, -dot(normal, v0), boxHalf) {
+		return false
+	}
+
+	return true
+}
+
+func axisTestOverlap(axis, v0, v1, v2, boxHalf [3]float64) bool {
+	if axis[0] == 0 && axis[1] == 0 && axis[2] == 0 {
+		return true
+	}
+
+	p0, p1, p2 := dot(v0, axis), dot(v1, axis), dot(v2, axis)
+	minP := math.Min(p0, math.Min(p1, p2))
+	maxP := math.Max(p0, math.Max(p1, p2))
+
+	rad := boxHalf[0]*math.Abs(axis[0]) + boxHalf[1]*math.Abs(axis[1]) + boxHalf[2]*math.Abs(axis[2])
+
+	return !(minP > rad || maxP < -rad)
+}
+
+func planeBoxOverlap(normal [3]float64, d float64, boxHalf [3]float64) bool {
+	var vMin, vMax [3]float64
+	for i := 0; i < 3; i++ {
+		if normal[i] > 0 {
+			vMin[i], vMax[i] = -boxHalf[i], boxHalf[i]
+		} else {
+			vMin[i], vMax[i] = boxHalf[i], -boxHalf[i]
+		}
+	}
+
+	if dot(normal, vMin)+d > 0 {
+		return false
+	}
+	return dot(normal, vMax)+d >= 0
+}
+
+// rayDir is the direction pointInsideMesh casts its parity ray along. It
+// is deliberately off every axis: a ray along a coordinate axis (e.g.
+// (1,0,0)) grazes the shared diagonal edge of any axis-aligned quad's two
+// triangles whenever the sample point lies exactly on that seam (as the
+// center of a voxelized cube routinely does), double-counting or missing
+// the crossing. The irrational-looking components keep it from lining up
+// with any axis-aligned mesh feature.
+var rayDir = [3]float64{1, 0.0072921, 0.0039183}
+
+// pointInsideMesh casts a ray from p along rayDir and checks whether it
+// crosses the mesh surface an odd number of times.
+func pointInsideMesh(tris []Triangle, bvh *bvhNode, p [3]float64) bool {
+	// rayDir drifts in Y/Z as it travels, so the only bound the broad
+	// phase can assume is that the ray never moves backward along X.
+	rayBox := aabb{
+		min: [3]float64{p[0], math.Inf(-1), math.Inf(-1)},
+		max: [3]float64{math.Inf(1), math.Inf(1), math.Inf(1)},
+	}
+
+	var candidates []int
+	bvh.query(rayBox, &candidates)
+
+	count := 0
+	for _, i := range candidates {
+		if rayIntersectsTriangle(p, rayDir, tris[i]) {
+			count++
+		}
+	}
+	return count%2 == 1
+}
+
+// rayIntersectsTriangle is the Moller-Trumbore ray/triangle test.
+func rayIntersectsTriangle(origin, dir [3]float64, tri Triangle) bool {
+	v0, v1, v2 := tri.V[0], tri.V[1], tri.V[2]
+
+	e1 := sub(v1, v0)
+	e2 := sub(v2, v0)
+
+	h := cross(dir, e2)
+	a := dot(e1, h)
+	if math.Abs(a) < 1e-12 {
+		return false
+	}
+
+	f := 1 / a
+	s := sub(origin, v0)
+	u := f * dot(s, h)
+	if u < 0 || u > 1 {
+		return false
+	}
+
+	q := cross(s, e1)
+	v := f * dot(dir, q)
+	if v < 0 || u+v > 1 {
+		return false
+	}
+
+	t := f * dot(e2, q)
+	return t > 1e-9
+}
@@ -0,0 +1,145 @@
+//
+// Copyright (c) 2020, Jason S. McMullan <jason.mcmullan@gmail.com>
+//
+
+// Package mesh voxelizes triangle meshes (as loaded from e.g. STL or OBJ
+// files) into a voctree.Voctree.
+package mesh
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/ezrec/voctree"
+)
+
+// Triangle is a single triangle in voxel space, as three vertices. The
+// Attribute byte is carried through unused by Voxelize itself, for
+// callers that need to tell triangles apart (e.g. per-face materials).
+type Triangle struct {
+	V         [3][3]float64
+	Attribute byte
+}
+
+// Options controls how Voxelize fills a Voctree from a triangle mesh.
+type Options struct {
+	// Shell voxelizes only the triangle surfaces. The default fills the
+	// mesh's interior solid too.
+	Shell bool
+
+	// Transform, if set, maps an input mesh vertex into voxel space
+	// before voxelization. If nil, input coordinates are used as-is.
+	Transform func(p [3]float64) [3]float64
+
+	// Progress, if set, is called as each of the root's 8 octants
+	// finishes, with the number of octants done and the total (8). A
+	// single-voxel tree has no octants and reports once, as (1, 1).
+	Progress func(done, total int)
+}
+
+// Voxelize rasterizes tris into tree, setting fill at every voxel a
+// triangle's surface touches and, unless opts.Shell, every voxel
+// enclosed by the mesh.
+func Voxelize(tree *voctree.Voctree, tris []Triangle, fill color.Gray, opts Options) error {
+	if tree == nil {
+		return fmt.Errorf("mesh: tree is nil")
+	}
+	if len(tris) == 0 {
+		return nil
+	}
+
+	if opts.Transform != nil {
+		xformed := make([]Triangle, len(tris))
+		for i, tri := range tris {
+			xformed[i].Attribute = tri.Attribute
+			for v := 0; v < 3; v++ {
+				xformed[i].V[v] = opts.Transform(tri.V[v])
+			}
+		}
+		tris = xformed
+	}
+
+	idx := make([]int, len(tris))
+	for i := range idx {
+		idx[i] = i
+	}
+	bvh := buildBVH(tris, idx)
+
+	root := voctree.Cube{SideShift: tree.SideShift}
+
+	if root.SideShift == 0 {
+		voxelizeNode(tree, tris, bvh, root, fill, opts)
+		if opts.Progress != nil {
+			opts.Progress(1, 1)
+		}
+		return nil
+	}
+
+	const total = 8
+	for i := 0; i < total; i++ {
+		voxelizeNode(tree, tris, bvh, childCube(root, i), fill, opts)
+		if opts.Progress != nil {
+			opts.Progress(i+1, total)
+		}
+	}
+
+	return nil
+}
+
+func voxelizeNode(tree *voctree.Voctree, tris []Triangle, bvh *bvhNode, cube voctree.Cube, fill color.Gray, opts Options) {
+	box := cubeAABB(cube)
+
+	var candidates []int
+	bvh.query(box, &candidates)
+
+	if len(candidates) == 0 {
+		if !opts.Shell && pointInsideMesh(tris, bvh, box.center()) {
+			min, max := cubeMinMax(cube)
+			tree.Fill(min, max, fill)
+		}
+		return
+	}
+
+	if cube.SideShift == 0 {
+		center, half := box.center(), box.half()
+		for _, i := range candidates {
+			if triangleIntersectsAABB(tris[i], center, half) {
+				tree.Set(cube.Point, fill)
+				return
+			}
+		}
+		// The BVH's broad-phase AABB overlap can be a false positive: a
+		// candidate triangle's bounding box reached this voxel without
+		// its actual surface touching it. Fall back to the same parity
+		// test used when there were no candidates at all.
+		if !opts.Shell && pointInsideMesh(tris, bvh, center) {
+			tree.Set(cube.Point, fill)
+		}
+		return
+	}
+
+	for i := 0; i < 8; i++ {
+		voxelizeNode(tree, tris, bvh, childCube(cube, i), fill, opts)
+	}
+}
+
+// childCube returns the Cube of octant i directly beneath cube.
+func childCube(cube voctree.Cube, i int) voctree.Cube {
+	side := uint16(1) << (cube.SideShift - 1)
+	return voctree.Cube{
+		SideShift: cube.SideShift - 1,
+		Point: voctree.Point{
+			X: cube.Point.X + uint16((i>>0)&1)*side,
+			Y: cube.Point.Y + uint16((i>>1)&1)*side,
+			Z: cube.Point.Z + uint16((i>>2)&1)*side,
+		},
+	}
+}
+
+// cubeMinMax returns the inclusive integer voxel bounds of cube.
+func cubeMinMax(cube voctree.Cube) (min, max voctree.Point) {
+	size := uint16(1) << cube.SideShift
+	min = cube.Point
+	max = voctree.Point{X: min.X + size - 1, Y: min.Y + size - 1, Z: min.Z + size - 1}
+	return
+}
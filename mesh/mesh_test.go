@@ -0,0 +1,76 @@
+//
+// Copyright (c) 2020, Jason S. McMullan <jason.mcmullan@gmail.com>
+//
+
+package mesh
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/ezrec/voctree"
+)
+
+// cubeTriangles returns the 12 triangles of an axis-aligned box
+// [min, max].
+func cubeTriangles(min, max [3]float64) []Triangle {
+	c := [8][3]float64{
+		{min[0], min[1], min[2]}, {max[0], min[1], min[2]},
+		{max[0], max[1], min[2]}, {min[0], max[1], min[2]},
+		{min[0], min[1], max[2]}, {max[0], min[1], max[2]},
+		{max[0], max[1], max[2]}, {min[0], max[1], max[2]},
+	}
+
+	quads := [6][4]int{
+		{0, 1, 2, 3}, // -Z
+		{4, 5, 6, 7}, // +Z
+		{0, 1, 5, 4}, // -Y
+		{3, 2, 6, 7}, // +Y
+		{0, 3, 7, 4}, // -X
+		{1, 2, 6, 5}, // +X
+	}
+
+	var tris []Triangle
+	for _, q := range quads {
+		tris = append(tris,
+			Triangle{V: [3][3]float64{c[q[0]], c[q[1]], c[q[2]]}},
+			Triangle{V: [3][3]float64{c[q[0]], c[q[2]], c[q[3]]}},
+		)
+	}
+	return tris
+}
+
+func TestVoxelizeSolidCube(t *testing.T) {
+	tree := voctree.NewVoctree(16, 16)
+
+	tris := cubeTriangles([3]float64{2, 2, 2}, [3]float64{6, 6, 6})
+
+	fill := color.Gray{Y: 0xff}
+	if err := Voxelize(tree, tris, fill, Options{}); err != nil {
+		t.Fatalf("Voxelize: %v", err)
+	}
+
+	if got := tree.At(voctree.Point{X: 4, Y: 4, Z: 4}); got != fill {
+		t.Fatalf("interior voxel: expected %+v, got %+v", fill, got)
+	}
+
+	if got := tree.At(voctree.Point{X: 0, Y: 0, Z: 0}); got != voctree.DefaultColor {
+		t.Fatalf("exterior voxel: expected %+v, got %+v", voctree.DefaultColor, got)
+	}
+}
+
+func TestVoxelizeShell(t *testing.T) {
+	tree := voctree.NewVoctree(16, 16)
+
+	tris := cubeTriangles([3]float64{2, 2, 2}, [3]float64{6, 6, 6})
+
+	fill := color.Gray{Y: 0xff}
+	if err := Voxelize(tree, tris, fill, Options{Shell: true}); err != nil {
+		t.Fatalf("Voxelize: %v", err)
+	}
+
+	// The shell-only voxelization must not fill the cube's interior
+	if got := tree.At(voctree.Point{X: 4, Y: 4, Z: 4}); got != voctree.DefaultColor {
+		t.Fatalf("interior voxel: expected %+v (unfilled), got %+v", voctree.DefaultColor, got)
+	}
+}
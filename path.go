@@ -0,0 +1,130 @@
+//
+// Copyright (c) 2020, Jason S. McMullan <jason.mcmullan@gmail.com>
+//
+
+package voctree
+
+// maxPathLen is the deepest a Path can address: 21 levels covers the full
+// 16-bit (uint16) coordinate range used by Point.
+const maxPathLen = 21
+
+// Path is a packed root-to-node address: a sequence of up to maxPathLen
+// 3-bit octant indices, in the same order octIndex would produce them
+// while descending from a tree's root. It gives O(depth) node identity
+// and lookup without recomputing octant indices from a Point each time.
+type Path struct {
+	bits uint64
+	len  uint8
+}
+
+// Len returns the number of octant indices pushed onto p.
+func (p Path) Len() int {
+	return int(p.len)
+}
+
+// Get returns the octant index pushed at depth i (0 is the index closest
+// to the root).
+func (p Path) Get(i int) uint8 {
+	return uint8((p.bits >> (uint(i) * 3)) & 0x7)
+}
+
+// Push appends idx, the octant index of the next level down, to p.
+func (p *Path) Push(idx uint8) {
+	if p.len >= maxPathLen {
+		panic("voctree: Path exceeds maxPathLen")
+	}
+
+	p.bits |= uint64(idx&0x7) << (uint(p.len) * 3)
+	p.len++
+}
+
+// Pop removes and returns the deepest octant index in p.
+func (p *Path) Pop() (idx uint8) {
+	p.len--
+	shift := uint(p.len) * 3
+	idx = uint8((p.bits >> shift) & 0x7)
+	p.bits &^= uint64(0x7) << shift
+	return
+}
+
+// Parent returns p with its deepest octant index removed.
+func (p Path) Parent() (parent Path) {
+	parent = p
+	parent.Pop()
+	return
+}
+
+// PathOf returns the Path of the node that owns point within a tree
+// rooted at sideShift, i.e. the same sequence of octant indices octIndex
+// would produce while descending to it.
+func PathOf(point Point, sideShift uint8) (path Path) {
+	cube := Cube{SideShift: sideShift, Point: point}
+	for cube.SideShift > 0 {
+		var index int
+		index, cube = octIndex(cube)
+		path.Push(uint8(index))
+	}
+	return
+}
+
+// Cube reconstructs the Cube that path addresses within a tree rooted at
+// sideShift.
+func (path Path) Cube(sideShift uint8) (cube Cube) {
+	cube.SideShift = sideShift
+	for i := 0; i < path.Len(); i++ {
+		cube.SideShift--
+		side := uint16(1) << cube.SideShift
+		idx := path.Get(i)
+		if idx&(1<<0) != 0 {
+			cube.Point.X |= side
+		}
+		if idx&(1<<1) != 0 {
+			cube.Point.Y |= side
+		}
+		if idx&(1<<2) != 0 {
+			cube.Point.Z |= side
+		}
+	}
+	return
+}
+
+// Walk performs a depth-first traversal of v, starting at the root,
+// calling fn with each visited node's Path, Cube (its absolute min
+// corner and SideShift) and Vocelish. If fn returns false for a branch
+// node (a *VocelTree), Walk does not descend into its subtrees.
+func (v *Voctree) Walk(fn func(path Path, cube Cube, node Vocelish) bool) {
+	walk(Path{}, Cube{SideShift: v.SideShift}, v.Vocelish, fn)
+}
+
+func walk(path Path, cube Cube, node Vocelish, fn func(path Path, cube Cube, node Vocelish) bool) {
+	if !fn(path, cube, node) {
+		return
+	}
+
+	vt, ok := node.(*VocelTree)
+	if !ok {
+		return
+	}
+
+	for i, sub := range vt.subtree {
+		childPath := path
+		childPath.Push(uint8(i))
+
+		walk(childPath, cubeChild(cube, i), sub, fn)
+	}
+}
+
+// cubeChild returns the Cube of octant i directly beneath cube, using the
+// same absolute (min-corner) addressing as (Path).Cube.
+func cubeChild(cube Cube, i int) (child Cube) {
+	side := uint16(1) << (cube.SideShift - 1)
+	child = Cube{
+		SideShift: cube.SideShift - 1,
+		Point: Point{
+			X: cube.Point.X + uint16((i>>0)&1)*side,
+			Y: cube.Point.Y + uint16((i>>1)&1)*side,
+			Z: cube.Point.Z + uint16((i>>2)&1)*side,
+		},
+	}
+	return
+}
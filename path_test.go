@@ -0,0 +1,92 @@
+//
+// Copyright (c) 2020, Jason S. McMullan <jason.mcmullan@gmail.com>
+//
+
+package voctree
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestPathPushPop(t *testing.T) {
+	var p Path
+
+	for i := uint8(0); i < 8; i++ {
+		p.Push(i % 8)
+	}
+
+	if p.Len() != 8 {
+		t.Fatalf("expected %v, got %v", 8, p.Len())
+	}
+
+	for i := uint8(0); i < 8; i++ {
+		if got := p.Get(int(i)); got != i%8 {
+			t.Fatalf("Get(%v): expected %v, got %v", i, i%8, got)
+		}
+	}
+
+	for i := 7; i >= 0; i-- {
+		if got := p.Pop(); got != uint8(i%8) {
+			t.Fatalf("Pop(): expected %v, got %v", i%8, got)
+		}
+	}
+
+	if p.Len() != 0 {
+		t.Fatalf("expected %v, got %v", 0, p.Len())
+	}
+}
+
+func TestPathOfAndCube(t *testing.T) {
+	const sideShift = 7
+
+	point := Point{X: 42, Y: 91, Z: 13}
+
+	path := PathOf(point, sideShift)
+	if path.Len() != sideShift {
+		t.Fatalf("expected %v, got %v", sideShift, path.Len())
+	}
+
+	cube := path.Cube(sideShift)
+	if cube.SideShift != 0 {
+		t.Fatalf("expected a leaf cube, got SideShift %v", cube.SideShift)
+	}
+	if cube.Point != point {
+		t.Fatalf("expected %+v, got %+v", point, cube.Point)
+	}
+}
+
+func TestVoctreeWalk(t *testing.T) {
+	const testX = 32
+	const testY = 32
+
+	v := NewVoctree(testX, testY)
+	// Two voxels in different top-level octants, so the root has more
+	// than one *VocelTree child to prune below.
+	v.Set(Point{X: 0, Y: 0, Z: 0}, color.Gray{Y: 0xff})
+	v.Set(Point{X: 16, Y: 16, Z: 16}, color.Gray{Y: 0x7f})
+
+	var leaves int
+	var prunedTrees int
+	v.Walk(func(path Path, cube Cube, node Vocelish) bool {
+		if _, ok := node.(*VocelTree); !ok {
+			leaves++
+			return true
+		}
+
+		// Only the root itself is allowed to descend; every other
+		// *VocelTree is pruned
+		if path.Len() > 0 {
+			prunedTrees++
+			return false
+		}
+		return true
+	})
+
+	if leaves == 0 {
+		t.Fatalf("expected Walk to visit at least one leaf")
+	}
+	if prunedTrees == 0 {
+		t.Fatalf("expected Walk to prune at least one subtree")
+	}
+}
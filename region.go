@@ -0,0 +1,217 @@
+//
+// Copyright (c) 2020, Jason S. McMullan <jason.mcmullan@gmail.com>
+//
+
+package voctree
+
+import (
+	"image/color"
+)
+
+// boxRelation describes how a Cube relates to an axis-aligned query box.
+type boxRelation int
+
+const (
+	boxOutside boxRelation = iota
+	boxPartial
+	boxInside
+)
+
+// cubeBoxRelation tests cube (by its absolute min corner and size) against
+// the inclusive box [min, max].
+func cubeBoxRelation(cube Cube, min, max Point) boxRelation {
+	size := uint32(1) << cube.SideShift
+
+	cMinX, cMaxX := uint32(cube.Point.X), uint32(cube.Point.X)+size-1
+	cMinY, cMaxY := uint32(cube.Point.Y), uint32(cube.Point.Y)+size-1
+	cMinZ, cMaxZ := uint32(cube.Point.Z), uint32(cube.Point.Z)+size-1
+
+	if cMaxX < uint32(min.X) || cMinX > uint32(max.X) ||
+		cMaxY < uint32(min.Y) || cMinY > uint32(max.Y) ||
+		cMaxZ < uint32(min.Z) || cMinZ > uint32(max.Z) {
+		return boxOutside
+	}
+
+	if cMinX >= uint32(min.X) && cMaxX <= uint32(max.X) &&
+		cMinY >= uint32(min.Y) && cMaxY <= uint32(max.Y) &&
+		cMinZ >= uint32(min.Z) && cMaxZ <= uint32(max.Z) {
+		return boxInside
+	}
+
+	return boxPartial
+}
+
+// leafColor returns the uniform color of a Vocel1 or VocelEmpty leaf.
+func leafColor(node Vocelish) (pixel color.Gray, ok bool) {
+	switch n := node.(type) {
+	case *Vocel1:
+		return n.pixel, true
+	case *VocelEmpty:
+		return DefaultColor, true
+	default:
+		return color.Gray{}, false
+	}
+}
+
+// splitLeaf expands a uniform leaf of the given pixel into the next level
+// down: a Vocel8 at the pixel level (sideShift == 1), or a VocelTree of 8
+// uniform leaves otherwise.
+func splitLeaf(pixel color.Gray, sideShift uint8) Vocelish {
+	if sideShift == 1 {
+		v8 := &Vocel8{}
+		for i := range v8.pixel {
+			v8.pixel[i] = pixel
+		}
+		return v8
+	}
+
+	var subtree [8]Vocelish
+	for i := range subtree {
+		if pixel == DefaultColor {
+			subtree[i] = emptyNode
+		} else {
+			subtree[i] = &Vocel1{pixel: pixel}
+		}
+	}
+	return &VocelTree{subtree: subtree}
+}
+
+// EachInBox calls fn with the Cube and pixel of every uniform region that
+// lies within the inclusive box [min, max], including regions at
+// DefaultColor, batching uniform subtrees into a
+// single call rather than visiting them voxel-by-voxel. Iteration stops
+// early if fn returns false.
+func (v *Voctree) EachInBox(min, max Point, fn func(cube Cube, pixel color.Gray) bool) {
+	eachInBox(Cube{SideShift: v.SideShift}, v.Vocelish, min, max, fn)
+}
+
+func eachInBox(cube Cube, node Vocelish, min, max Point, fn func(cube Cube, pixel color.Gray) bool) bool {
+	if cubeBoxRelation(cube, min, max) == boxOutside {
+		return true
+	}
+
+	if pixel, ok := leafColor(node); ok {
+		return eachInBoxUniform(cube, pixel, min, max, fn)
+	}
+
+	if v8, ok := node.(*Vocel8); ok {
+		for i := range v8.pixel {
+			if !eachInBoxUniform(cubeChild(cube, i), v8.pixel[i], min, max, fn) {
+				return false
+			}
+		}
+		return true
+	}
+
+	vt := node.(*VocelTree)
+	for i, sub := range vt.subtree {
+		if !eachInBox(cubeChild(cube, i), sub, min, max, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// eachInBoxUniform reports cube (or, on partial overlap, its synthetic
+// octants) to fn. Since every descendant of a uniform leaf shares pixel,
+// this stops as soon as a sub-cube is fully inside or fully outside the
+// box, visiting O(touched octants) rather than every voxel.
+func eachInBoxUniform(cube Cube, pixel color.Gray, min, max Point, fn func(cube Cube, pixel color.Gray) bool) bool {
+	switch cubeBoxRelation(cube, min, max) {
+	case boxOutside:
+		return true
+	case boxInside:
+		return fn(cube, pixel)
+	}
+
+	for i := 0; i < 8; i++ {
+		if !eachInBoxUniform(cubeChild(cube, i), pixel, min, max, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// Fill sets every voxel in the inclusive box [min, max] to pixel.
+// Subtrees fully inside the box are replaced with a single Vocel1 in one
+// step, and subtrees fully outside are left untouched, so the cost is
+// O(nodes touched) rather than O(volume of the box).
+func (v *Voctree) Fill(min, max Point, pixel color.Gray) {
+	v.Vocelish = fillNode(v.Vocelish, Cube{SideShift: v.SideShift}, min, max, pixel)
+}
+
+func fillNode(node Vocelish, cube Cube, min, max Point, pixel color.Gray) Vocelish {
+	switch cubeBoxRelation(cube, min, max) {
+	case boxOutside:
+		return node
+	case boxInside:
+		if pixel == DefaultColor {
+			return emptyNode
+		}
+		return &Vocel1{pixel: pixel}
+	}
+
+	// Partial overlap: cube.SideShift must be > 0 here, since a unit cube
+	// can never partially overlap a box.
+	switch n := node.(type) {
+	case *Vocel8:
+		if cube.SideShift > 1 {
+			// Above the pixel level an octant may only partially overlap
+			// the box, so expand into a VocelTree and fill each octant.
+			return fillNode(&VocelTree{subtree: ensureOctants(n)}, cube, min, max, pixel)
+		}
+		for i := range n.pixel {
+			if cubeBoxRelation(cubeChild(cube, i), min, max) == boxInside {
+				n.pixel[i] = pixel
+			}
+		}
+		return coalesceVocel8(n)
+
+	case *VocelTree:
+		for i := range n.subtree {
+			n.subtree[i] = fillNode(n.subtree[i], cubeChild(cube, i), min, max, pixel)
+		}
+		return coalesceOctant(n.subtree)
+
+	default:
+		leaf, _ := leafColor(node)
+		return fillNode(splitLeaf(leaf, cube.SideShift), cube, min, max, pixel)
+	}
+}
+
+// coalesceVocel8 collapses a Vocel8 into a Vocel1 or VocelEmpty if all 8
+// of its pixels now agree.
+func coalesceVocel8(v8 *Vocel8) Vocelish {
+	for i := 1; i < len(v8.pixel); i++ {
+		if v8.pixel[i] != v8.pixel[0] {
+			return v8
+		}
+	}
+
+	if v8.pixel[0] == DefaultColor {
+		return emptyNode
+	}
+	return &Vocel1{pixel: v8.pixel[0]}
+}
+
+// CopyRegion copies the inclusive box [srcMin, srcMax] of v into dst, so
+// that the srcMin corner lands at dstOrigin.
+func (v *Voctree) CopyRegion(dst *Voctree, dstOrigin, srcMin, srcMax Point) {
+	v.EachInBox(srcMin, srcMax, func(cube Cube, pixel color.Gray) bool {
+		size := uint16(1) << cube.SideShift
+
+		dstMin := Point{
+			X: dstOrigin.X + (cube.Point.X - srcMin.X),
+			Y: dstOrigin.Y + (cube.Point.Y - srcMin.Y),
+			Z: dstOrigin.Z + (cube.Point.Z - srcMin.Z),
+		}
+		dstMax := Point{
+			X: dstMin.X + size - 1,
+			Y: dstMin.Y + size - 1,
+			Z: dstMin.Z + size - 1,
+		}
+
+		dst.Fill(dstMin, dstMax, pixel)
+		return true
+	})
+}
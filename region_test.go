@@ -0,0 +1,78 @@
+//
+// Copyright (c) 2020, Jason S. McMullan <jason.mcmullan@gmail.com>
+//
+
+package voctree
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestVoctreeFillAndEachInBox(t *testing.T) {
+	const testX = 64
+	const testY = 64
+
+	v := NewVoctree(testX, testY)
+
+	white := color.Gray{Y: 0xff}
+	// An octant-aligned box: exactly the root's first child, so it
+	// collapses to a single node and a single EachInBox callback.
+	v.Fill(Point{X: 0, Y: 0, Z: 0}, Point{X: 31, Y: 31, Z: 31}, white)
+
+	var regions int
+	v.EachInBox(Point{X: 0, Y: 0, Z: 0}, Point{X: 31, Y: 31, Z: 31}, func(cube Cube, pixel color.Gray) bool {
+		regions++
+		if pixel != white {
+			t.Fatalf("expected %+v, got %+v", white, pixel)
+		}
+		return true
+	})
+	if regions != 1 {
+		t.Fatalf("expected a single coalesced region, got %v", regions)
+	}
+
+	// Spot-check a few points directly via At
+	for _, p := range []Point{{X: 0, Y: 0, Z: 0}, {X: 31, Y: 31, Z: 31}} {
+		if got := v.At(p); got != white {
+			t.Fatalf("At(%+v): expected %+v, got %+v", p, white, got)
+		}
+	}
+	for _, p := range []Point{{X: 32, Y: 0, Z: 0}, {X: 63, Y: 63, Z: 63}} {
+		if got := v.At(p); got != DefaultColor {
+			t.Fatalf("At(%+v): expected %+v, got %+v", p, DefaultColor, got)
+		}
+	}
+
+	// Fill back to DefaultColor should coalesce the whole tree back down
+	v.Fill(Point{X: 0, Y: 0, Z: 0}, Point{X: testX - 1, Y: testY - 1, Z: testY - 1}, DefaultColor)
+	if v.Nodes() != 0 {
+		t.Fatalf("expected %v, got %v", 0, v.Nodes())
+	}
+}
+
+func TestVoctreeCopyRegion(t *testing.T) {
+	const size = 32
+
+	src := NewVoctree(size, size)
+	dst := NewVoctree(size, size)
+
+	red := color.Gray{Y: 0x40}
+	src.Fill(Point{X: 4, Y: 4, Z: 4}, Point{X: 11, Y: 11, Z: 11}, red)
+
+	src.CopyRegion(dst, Point{X: 0, Y: 0, Z: 0}, Point{X: 4, Y: 4, Z: 4}, Point{X: 11, Y: 11, Z: 11})
+
+	for z := uint16(0); z < 8; z++ {
+		for y := uint16(0); y < 8; y++ {
+			for x := uint16(0); x < 8; x++ {
+				if got := dst.At(Point{X: x, Y: y, Z: z}); got != red {
+					t.Fatalf("At(%v,%v,%v): expected %+v, got %+v", x, y, z, red, got)
+				}
+			}
+		}
+	}
+
+	if got := dst.At(Point{X: 8, Y: 8, Z: 8}); got != DefaultColor {
+		t.Fatalf("expected copy not to spill past its bounds, got %+v", got)
+	}
+}
@@ -0,0 +1,280 @@
+//
+// Copyright (c) 2020, Jason S. McMullan <jason.mcmullan@gmail.com>
+//
+
+package voctree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"io"
+)
+
+// Compact binary on-disk format for a Voctree.
+//
+// Layout:
+//
+//	magic[4]   "VOC1"
+//	version    uint8
+//	sideShift  uint8
+//	rect       4 x int32 (Min.X, Min.Y, Max.X, Max.Y), big-endian
+//	root       node record
+//
+// A node record is a 1-byte tag, followed by the node's payload, followed
+// by a big-endian CRC32 (IEEE) of that payload:
+//
+//	tagVocel1  pixel[1]
+//	tagVocel8  ctrl[1] pixel[1 or 8]   (ctrl == vocel8Uniform: one pixel repeated 8x)
+//	tagTree    8 x node record, back to back
+//	tagEmpty   (no payload, no CRC)    the shared VocelEmpty leaf, just the tag byte
+const (
+	voctreeMagic   = "VOC1"
+	voctreeVersion = 1
+)
+
+// Node tags used by the on-disk format.
+const (
+	tagEmpty  = 0x00
+	tagVocel1 = 0x01
+	tagVocel8 = 0x08
+	tagTree   = 0xFF
+)
+
+// Vocel8 payload control byte: whether the 8 pixels are stored literally,
+// or as a single repeated value.
+const (
+	vocel8Literal = 0
+	vocel8Uniform = 1
+)
+
+// WriteTo writes v to w in the compact Voctree binary format.
+func (v *Voctree) WriteTo(w io.Writer) (n int64, err error) {
+	written, err := io.WriteString(w, voctreeMagic)
+	n += int64(written)
+	if err != nil {
+		return
+	}
+
+	written, err = w.Write([]byte{voctreeVersion, v.SideShift})
+	n += int64(written)
+	if err != nil {
+		return
+	}
+
+	rect := [4]int32{int32(v.Min.X), int32(v.Min.Y), int32(v.Max.X), int32(v.Max.Y)}
+	if err = binary.Write(w, binary.BigEndian, rect); err != nil {
+		return
+	}
+	n += 4 * 4
+
+	nn, err := v.Vocelish.WriteTo(w)
+	n += nn
+	return
+}
+
+// ReadFrom reads a Voctree previously written by (*Voctree).WriteTo.
+func ReadFrom(r io.Reader) (v *Voctree, err error) {
+	var magic [4]byte
+	if _, err = io.ReadFull(r, magic[:]); err != nil {
+		return
+	}
+	if string(magic[:]) != voctreeMagic {
+		err = fmt.Errorf("voctree: bad magic %q", magic)
+		return
+	}
+
+	var header [2]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return
+	}
+	version, sideShift := header[0], header[1]
+	if version != voctreeVersion {
+		err = fmt.Errorf("voctree: unsupported version %v", version)
+		return
+	}
+
+	var rect [4]int32
+	if err = binary.Read(r, binary.BigEndian, &rect); err != nil {
+		return
+	}
+
+	root, err := readNode(r)
+	if err != nil {
+		return
+	}
+
+	v = &Voctree{
+		Rectangle: image.Rect(int(rect[0]), int(rect[1]), int(rect[2]), int(rect[3])),
+		Vocelish:  root,
+		SideShift: sideShift,
+	}
+	return
+}
+
+// writeRecord writes a tagged node record: tag, payload, CRC32(payload).
+func writeRecord(w io.Writer, tag byte, payload []byte) (n int64, err error) {
+	written, err := w.Write([]byte{tag})
+	n += int64(written)
+	if err != nil {
+		return
+	}
+
+	written, err = w.Write(payload)
+	n += int64(written)
+	if err != nil {
+		return
+	}
+
+	var crcBuf [4]byte
+	binary.BigEndian.PutUint32(crcBuf[:], crc32.ChecksumIEEE(payload))
+	written, err = w.Write(crcBuf[:])
+	n += int64(written)
+	return
+}
+
+// verifyCRC reads a trailing CRC32 from r and checks it against payload.
+func verifyCRC(r io.Reader, payload []byte) (err error) {
+	var crcBuf [4]byte
+	if _, err = io.ReadFull(r, crcBuf[:]); err != nil {
+		return
+	}
+
+	want := binary.BigEndian.Uint32(crcBuf[:])
+	if got := crc32.ChecksumIEEE(payload); got != want {
+		err = fmt.Errorf("voctree: crc32 mismatch: want %08x, got %08x", want, got)
+	}
+	return
+}
+
+// readNode reads a single tagged node record, recursing for tagTree.
+func readNode(r io.Reader) (node Vocelish, err error) {
+	var tagBuf [1]byte
+	if _, err = io.ReadFull(r, tagBuf[:]); err != nil {
+		return
+	}
+
+	switch tagBuf[0] {
+	case tagVocel1:
+		var payload [1]byte
+		if _, err = io.ReadFull(r, payload[:]); err != nil {
+			return
+		}
+		if err = verifyCRC(r, payload[:]); err != nil {
+			return
+		}
+		node = &Vocel1{pixel: color.Gray{Y: payload[0]}}
+
+	case tagVocel8:
+		var ctrl [1]byte
+		if _, err = io.ReadFull(r, ctrl[:]); err != nil {
+			return
+		}
+
+		rest := make([]byte, 8)
+		if ctrl[0] == vocel8Uniform {
+			rest = rest[:1]
+		}
+		if _, err = io.ReadFull(r, rest); err != nil {
+			return
+		}
+
+		payload := append(ctrl[:], rest...)
+		if err = verifyCRC(r, payload); err != nil {
+			return
+		}
+
+		v8 := &Vocel8{}
+		if ctrl[0] == vocel8Uniform {
+			for i := range v8.pixel {
+				v8.pixel[i] = color.Gray{Y: rest[0]}
+			}
+		} else {
+			for i := range v8.pixel {
+				v8.pixel[i] = color.Gray{Y: rest[i]}
+			}
+		}
+		node = v8
+
+	case tagTree:
+		var buf bytes.Buffer
+		tee := io.TeeReader(r, &buf)
+
+		vt := &VocelTree{}
+		for i := range vt.subtree {
+			if vt.subtree[i], err = readNode(tee); err != nil {
+				return
+			}
+		}
+
+		if err = verifyCRC(r, buf.Bytes()); err != nil {
+			return
+		}
+		node = vt
+
+	case tagEmpty:
+		node = emptyNode
+
+	default:
+		err = fmt.Errorf("voctree: unknown node tag 0x%02x", tagBuf[0])
+	}
+
+	return
+}
+
+// WriteTo writes just the node's tag byte to w: a VocelEmpty carries no
+// state of its own (it always reads back as the shared emptyNode), so it
+// skips the payload and CRC32 that every other record pays for.
+func (ve *VocelEmpty) WriteTo(w io.Writer) (n int64, err error) {
+	written, err := w.Write([]byte{tagEmpty})
+	n = int64(written)
+	return
+}
+
+// WriteTo writes the node's tag, payload and CRC32(payload) to w.
+func (v1 *Vocel1) WriteTo(w io.Writer) (n int64, err error) {
+	return writeRecord(w, tagVocel1, []byte{v1.pixel.Y})
+}
+
+// WriteTo writes the node's tag, payload and CRC32(payload) to w.
+//
+// If all 8 pixels share the same value, the payload is stored as a single
+// repeated byte rather than all 8, per the vocel8Uniform control byte.
+func (v8 *Vocel8) WriteTo(w io.Writer) (n int64, err error) {
+	uniform := true
+	for _, p := range v8.pixel {
+		if p != v8.pixel[0] {
+			uniform = false
+			break
+		}
+	}
+
+	var payload []byte
+	if uniform {
+		payload = []byte{vocel8Uniform, v8.pixel[0].Y}
+	} else {
+		payload = make([]byte, 9)
+		payload[0] = vocel8Literal
+		for i, p := range v8.pixel {
+			payload[1+i] = p.Y
+		}
+	}
+
+	return writeRecord(w, tagVocel8, payload)
+}
+
+// WriteTo writes the node's tag, the serialized records of its 8 subtrees,
+// and a trailing CRC32 of that payload, to w.
+func (vt *VocelTree) WriteTo(w io.Writer) (n int64, err error) {
+	var buf bytes.Buffer
+	for _, sub := range vt.subtree {
+		if _, err = sub.WriteTo(&buf); err != nil {
+			return
+		}
+	}
+
+	return writeRecord(w, tagTree, buf.Bytes())
+}
@@ -0,0 +1,65 @@
+//
+// Copyright (c) 2020, Jason S. McMullan <jason.mcmullan@gmail.com>
+//
+
+package voctree
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestVoctreeSerializeRoundTrip(t *testing.T) {
+	const testX = 64
+	const testY = 64
+	const testZ = 64
+
+	v := NewVoctree(testX, testY)
+
+	white := image.NewGray(image.Rect(0, 0, testX, testY))
+	for l := 0; l < len(white.Pix); l++ {
+		white.Pix[l] = 0xff
+	}
+
+	for z := 0; z < testZ/2; z++ {
+		v.SetPlane(z, white)
+	}
+
+	// Poke a single non-uniform voxel in, so Vocel8 literal encoding is exercised.
+	v.Set(Point{X: 0, Y: 0, Z: 0}, color.Gray{Y: 0x7f})
+
+	var buf bytes.Buffer
+	if _, err := v.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	got, err := ReadFrom(&buf)
+	if err != nil {
+		t.Fatalf("ReadFrom: %v", err)
+	}
+
+	if got.Bounds() != v.Bounds() {
+		t.Fatalf("expected bounds %v, got %v", v.Bounds(), got.Bounds())
+	}
+
+	if got.SideShift != v.SideShift {
+		t.Fatalf("expected SideShift %v, got %v", v.SideShift, got.SideShift)
+	}
+
+	for z := 0; z < testZ/2+1; z++ {
+		want := v.GetPlane(z)
+		have := got.GetPlane(z)
+		if !bytes.Equal(want.Pix, have.Pix) {
+			t.Fatalf("plane %v: pixels differ after round-trip", z)
+		}
+	}
+}
+
+func TestVoctreeSerializeBadMagic(t *testing.T) {
+	buf := bytes.NewBufferString("nope")
+	if _, err := ReadFrom(buf); err == nil {
+		t.Fatalf("expected error for bad magic, got nil")
+	}
+}
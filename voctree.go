@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"io"
 )
 
 type Point struct {
@@ -55,6 +56,60 @@ type Vocelish interface {
 	At(cube Cube) (pixel color.Gray)
 	Nodes() (count int)
 	String(cube Cube) (str string)
+
+	// WriteTo serializes the node (and, for branch nodes, its subtrees)
+	// to w in the compact Voctree binary format. See serialize.go.
+	WriteTo(w io.Writer) (n int64, err error)
+}
+
+// DefaultColor is the uniform background color represented by VocelEmpty
+// nodes. Subtrees that coalesce down to this color become a single,
+// shared VocelEmpty leaf instead of an allocated Vocel1, which is what
+// keeps sparse, mostly-background volumes cheap.
+var DefaultColor = color.Gray{}
+
+// Leaf case: a uniform subtree at DefaultColor. Behaves like a Vocel1
+// holding DefaultColor, but carries no per-node storage: every VocelEmpty
+// use shares the same emptyNode instance.
+type VocelEmpty struct{}
+
+var emptyNode = &VocelEmpty{}
+
+func (ve *VocelEmpty) Nodes() (count int) {
+	return 0
+}
+
+func (ve *VocelEmpty) String(cube Cube) (str string) {
+	return fmt.Sprintf("ve{size: %v @(%+v), pixel: %+v}", (1 << cube.SideShift), cube.Point, DefaultColor)
+}
+
+/// Set - set the pixel of the volume
+///
+/// If the new pixel is not DefaultColor, split into 8 voxels
+func (ve *VocelEmpty) Set(cube Cube, pixel color.Gray) (vnew Vocelish) {
+	if pixel == DefaultColor {
+		vnew = ve
+		return
+	}
+
+	if cube.SideShift == 0 {
+		vnew = &Vocel1{pixel: pixel}
+		return
+	}
+
+	// Split!
+	v8 := &Vocel8{}
+	for i := 0; i < len(v8.pixel); i++ {
+		v8.pixel[i] = DefaultColor
+	}
+
+	vnew = v8.Set(cube, pixel)
+	return
+}
+
+func (ve *VocelEmpty) At(cube Cube) (pixel color.Gray) {
+	pixel = DefaultColor
+	return
 }
 
 // Leaf case: one pixel in the cube, no subtress
@@ -135,9 +190,12 @@ func (v8 *Vocel8) Set(cube Cube, pixel color.Gray) (vnew Vocelish) {
 			return
 		}
 
-		// Coalesce into a Vocel1
-		v1 := &Vocel1{pixel: pixel}
-		vnew = v1
+		// Coalesce into a VocelEmpty (if uniformly DefaultColor) or a Vocel1
+		if pixel == DefaultColor {
+			vnew = emptyNode
+		} else {
+			vnew = &Vocel1{pixel: pixel}
+		}
 		return
 	}
 
@@ -191,40 +249,53 @@ func (vt *VocelTree) Set(cube Cube, pixel color.Gray) (vnew Vocelish) {
 
 	vt.subtree[index] = vt.subtree[index].Set(here, pixel)
 
-	// If all the subtrees are Vocel1, the coalesce into a Vocel8
-	allVocel1 := true
-	for _, sub := range vt.subtree {
-		_, ok := sub.(*Vocel1)
-		if !ok {
-			allVocel1 = false
+	vnew = coalesceOctant(vt.subtree)
+	return
+}
+
+// coalesceOctant collapses 8 same-level subtrees into a single Vocel8,
+// Vocel1 or VocelEmpty when possible, or returns the VocelTree wrapping
+// them unchanged when it can't.
+func coalesceOctant(subtree [8]Vocelish) (vnew Vocelish) {
+	// If all the subtrees are Vocel1 or VocelEmpty leaves, collect their
+	// colors so we can try to coalesce into a Vocel8, Vocel1 or VocelEmpty
+	allLeaf := true
+	var pixels [8]color.Gray
+	for i, sub := range subtree {
+		switch leaf := sub.(type) {
+		case *Vocel1:
+			pixels[i] = leaf.pixel
+		case *VocelEmpty:
+			pixels[i] = DefaultColor
+		default:
+			allLeaf = false
+		}
+		if !allLeaf {
 			break
 		}
 	}
 
-	if !allVocel1 {
-		vnew = vt
+	if !allLeaf {
+		vnew = &VocelTree{subtree: subtree}
 		return
 	}
 
-	// Collect the pixels of all the subnodes
 	allSame := true
-	var pixels [8]color.Gray
-	for i, sub := range vt.subtree {
-		v1 := sub.(*Vocel1)
-		pixels[i] = v1.pixel
-		if i > 0 && pixels[i] != pixels[0] {
+	for i := 1; i < len(pixels); i++ {
+		if pixels[i] != pixels[0] {
 			allSame = false
+			break
 		}
-		vt.subtree[i] = nil
 	}
 
-	// If all the pixels are the same, use a Vocel1, otherwise use a Vocel8
+	// If all the pixels are the same, coalesce into a VocelEmpty (when it
+	// is DefaultColor) or a Vocel1; otherwise use a Vocel8
 	if !allSame {
-		v8 := &Vocel8{pixel: pixels}
-		vnew = v8
+		vnew = &Vocel8{pixel: pixels}
+	} else if pixels[0] == DefaultColor {
+		vnew = emptyNode
 	} else {
-		v1 := &Vocel1{pixel: pixels[0]}
-		vnew = v1
+		vnew = &Vocel1{pixel: pixels[0]}
 	}
 
 	return
@@ -257,7 +328,7 @@ func NewVoctree(sizex, sizey int) (v *Voctree) {
 
 	v = &Voctree{
 		Rectangle: image.Rect(0, 0, sizex, sizey),
-		Vocelish:  &Vocel1{},
+		Vocelish:  emptyNode,
 		SideShift: uint8(sideShift),
 	}
 
@@ -272,7 +343,7 @@ func (v *Voctree) resizeSideShift(z int) {
 		vt := &VocelTree{}
 		vt.subtree[0] = v.Vocelish
 		for i := 1; i < len(vt.subtree); i++ {
-			vt.subtree[i] = &Vocel1{}
+			vt.subtree[i] = emptyNode
 		}
 		v.Vocelish = vt
 	}
@@ -34,10 +34,11 @@ func TestVoctreeCreate(t *testing.T) {
 		v.SetPlane(z, black)
 	}
 
-	// This should only take one v element
-	if v.Nodes() != 1 {
+	// All-black is the DefaultColor, so this should stay the shared
+	// VocelEmpty leaf and cost zero nodes
+	if v.Nodes() != 0 {
 		fmt.Printf("%v\n", v)
-		t.Fatalf("expected %v, got %v", 1, v.Nodes())
+		t.Fatalf("expected %v, got %v", 0, v.Nodes())
 	}
 
 	// Set the bottom half of the v to all white
@@ -69,6 +70,35 @@ func TestVoctreeCreate(t *testing.T) {
 	}
 }
 
+func TestVoctreeEmptyDefaultColor(t *testing.T) {
+	const testX = 32
+	const testY = 32
+
+	v := NewVoctree(testX, testY)
+
+	if _, ok := v.Vocelish.(*VocelEmpty); !ok {
+		t.Fatalf("expected a fresh Voctree to start as a VocelEmpty, got %T", v.Vocelish)
+	}
+
+	// Setting a pixel to DefaultColor should leave the tree untouched
+	v.Set(Point{X: 0, Y: 0, Z: 0}, DefaultColor)
+	if v.Nodes() != 0 {
+		t.Fatalf("expected %v, got %v", 0, v.Nodes())
+	}
+
+	// Setting a pixel to a non-default color must materialize it
+	v.Set(Point{X: 0, Y: 0, Z: 0}, color.Gray{Y: 0xff})
+	if v.Nodes() == 0 {
+		t.Fatalf("expected a materialized tree, got %v nodes", v.Nodes())
+	}
+
+	// ... and setting it back to DefaultColor should coalesce back down
+	v.Set(Point{X: 0, Y: 0, Z: 0}, DefaultColor)
+	if v.Nodes() != 0 {
+		t.Fatalf("expected %v, got %v", 0, v.Nodes())
+	}
+}
+
 func TestVoctreeModify(t *testing.T) {
 	const testX = 128
 	const testY = 128